@@ -0,0 +1,104 @@
+// Command sss is a small CLI around the sss package: split a secret read
+// from stdin into share files, or combine a set of share files back into
+// the original secret.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/steveruckdashel/sss"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "split":
+		err = runSplit(os.Args[2:])
+	case "combine":
+		err = runCombine(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sss:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: sss split -n N -t T [-out prefix] < secret")
+	fmt.Fprintln(os.Stderr, "       sss combine share1 share2 ... > secret")
+}
+
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	n := fs.Int("n", 5, "number of shares to produce")
+	t := fs.Int("t", 3, "number of shares required to reconstruct the secret")
+	out := fs.String("out", "share", "output file prefix; shares are written to <prefix>.<x>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	secret, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("reading secret from stdin: %w", err)
+	}
+
+	shares, err := sss.Split(secret, *n, *t)
+	if err != nil {
+		return fmt.Errorf("splitting secret: %w", err)
+	}
+
+	for _, share := range shares {
+		data, err := share.Marshal()
+		if err != nil {
+			return fmt.Errorf("marshaling share %d: %w", share.X, err)
+		}
+		path := fmt.Sprintf("%s.%d", *out, share.X)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Fprintln(os.Stderr, "wrote", path)
+	}
+	return nil
+}
+
+func runCombine(args []string) error {
+	fs := flag.NewFlagSet("combine", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return fmt.Errorf("combine needs at least one share file")
+	}
+
+	shares := make([]sss.Share, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filepath.Clean(path), err)
+		}
+		if err := shares[i].Unmarshal(data); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	secret, err := sss.Combine(shares)
+	if err != nil {
+		return fmt.Errorf("combining shares: %w", err)
+	}
+
+	_, err = os.Stdout.Write(secret)
+	return err
+}
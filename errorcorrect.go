@@ -0,0 +1,255 @@
+// Fault-tolerant reconstruction via Berlekamp-Welch decoding.
+//
+// RecoverSecret and Combine have no way to tell a correct share from a
+// corrupted one: given n >= threshold shares where some are wrong, they
+// produce a wrong secret silently, since neither checks extra shares
+// against the interpolated polynomial. RecoverWithErrors tolerates up to maxErrors
+// corrupt shares, as long as n >= threshold + 2*maxErrors, and tells the
+// caller which share ids were bad.
+
+package sss
+
+import "errors"
+
+// RecoverWithErrors reconstructs the secret from shares (ids[i],
+// shareBytes[i]) even if up to maxErrors of them are corrupt, via
+// Berlekamp-Welch decoding over GF(256). It requires
+// len(ids) >= threshold + 2*maxErrors. It returns the recovered secret and
+// the subset of ids that decoding found to be corrupt.
+//
+// At the minimum share count (len(ids) == threshold + 2*maxErrors) the
+// linear system is exactly square: there are no spare equations left to
+// notice if the actual corruption exceeds maxErrors, so a share set with
+// more than maxErrors bad shares can come back as a wrong secret with a
+// nil error and an empty bad list. Callers who want that case caught
+// should supply more than the minimum number of shares, which gives the
+// consistency check in gf256GaussianSolve spare rows to fail on.
+func RecoverWithErrors(ids []byte, shareBytes [][]byte, threshold, maxErrors int) ([]byte, []byte, error) {
+	if len(ids) != len(shareBytes) {
+		return nil, nil, errors.New("sss: ids and shareBytes must be the same length")
+	}
+	if maxErrors < 0 {
+		return nil, nil, errors.New("sss: maxErrors must not be negative")
+	}
+	if len(ids) < threshold+2*maxErrors {
+		return nil, nil, errors.New("sss: not enough shares for threshold + 2*maxErrors")
+	}
+	for _, id := range ids {
+		if id == 0 {
+			return nil, nil, errors.New("sss: share id must be non-zero")
+		}
+	}
+	if len(shareBytes) == 0 {
+		return []byte{}, nil, nil
+	}
+	secretLen := len(shareBytes[0])
+
+	if maxErrors == 0 {
+		// No error tolerance requested: plain Lagrange interpolation.
+		secret, err := RecoverSecret(threshold, ids, shareBytes)
+		return secret, nil, err
+	}
+
+	secret := make([]byte, secretLen)
+	badVotes := make(map[byte]int)
+	for pos := 0; pos < secretLen; pos++ {
+		ys := make([]byte, len(ids))
+		for i := range ids {
+			ys[i] = shareBytes[i][pos]
+		}
+		byteVal, bad, err := berlekampWelchDecodeByte(ids, ys, threshold, maxErrors)
+		if err != nil {
+			return nil, nil, err
+		}
+		secret[pos] = byteVal
+		for _, id := range bad {
+			badVotes[id]++
+		}
+	}
+
+	// A genuinely corrupt share is wrong in every byte position; treat an
+	// id as bad if it was flagged for at least one byte, since a
+	// transport-level corruption of even a single byte still makes the
+	// share unusable.
+	var badIDs []byte
+	for _, id := range ids {
+		if badVotes[id] > 0 {
+			badIDs = append(badIDs, id)
+		}
+	}
+
+	return secret, badIDs, nil
+}
+
+// berlekampWelchDecodeByte runs the Berlekamp-Welch algorithm for a single
+// byte position: given points (ids[i], ys[i]), it finds the error locator
+// E(x) (monic, degree maxErrors) and Q(x) (degree < threshold+maxErrors)
+// such that Q(x_i) = ys[i]*E(x_i) for every i, then returns f(0) = Q(0)/E(0)
+// along with the ids where E has a root (the corrupt shares).
+func berlekampWelchDecodeByte(ids, ys []byte, threshold, maxErrors int) (byte, []byte, error) {
+	n := len(ids)
+	numQ := threshold + maxErrors // q_0 .. q_{numQ-1}
+	numE := maxErrors             // e_0 .. e_{numE-1}; E(x) = x^maxErrors + sum e_k x^k
+	numUnknowns := numQ + numE
+
+	// Row i encodes: sum_j q_j*x_i^j + sum_k (y_i*x_i^k)*e_k = y_i*x_i^maxErrors
+	// (GF(256) has characteristic 2, so subtraction is addition and signs
+	// never matter).
+	rows := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		row := make([]byte, numUnknowns+1)
+		x := ids[i]
+		y := ys[i]
+
+		xPow := byte(1)
+		for j := 0; j < numQ; j++ {
+			row[j] = xPow
+			xPow = gf256_mul(xPow, x)
+		}
+		xPow = byte(1)
+		for k := 0; k < numE; k++ {
+			row[numQ+k] = gf256_mul(y, xPow)
+			xPow = gf256_mul(xPow, x)
+		}
+		row[numUnknowns] = gf256_mul(y, xPow) // xPow is now x^maxErrors
+
+		rows[i] = row
+	}
+
+	solution, free, err := gf256GaussianSolve(rows, numUnknowns)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	qCoefs := solution[:numQ]
+	eCoefs := append(append([]byte{}, solution[numQ:]...), 1) // e_0..e_{maxErrors-1}, then the implicit leading 1
+
+	q0 := qCoefs[0]
+	e0 := eCoefs[0]
+	if e0 == 0 {
+		return 0, nil, errors.New("sss: too many corrupt shares to recover")
+	}
+	secretByte := gf256_div(q0, e0)
+
+	// If any e_k came from an under-determined (free) column, E(x) is only
+	// fixed up to an arbitrary common factor (x-r): Q(0)/E(0) still gives
+	// the right secret byte (see gf256GaussianSolve), but E's roots are not
+	// meaningful, and reporting one as a "corrupt" share id would blame an
+	// honest holder just because r happened to land on their id. That only
+	// happens when the actual error count is below maxErrors, so there is
+	// nothing to report.
+	for _, isFree := range free[numQ:] {
+		if isFree {
+			return secretByte, nil, nil
+		}
+	}
+
+	var bad []byte
+	for i, x := range ids {
+		if f(x, eCoefs) == 0 {
+			bad = append(bad, ids[i])
+		}
+	}
+
+	return secretByte, bad, nil
+}
+
+// gf256GaussianSolve solves the linear system given by rows (each row is
+// numUnknowns coefficients followed by the right-hand side) via Gaussian
+// elimination with partial pivoting over GF(256). It tolerates more rows
+// than unknowns, as Berlekamp-Welch produces when there are more shares
+// than the minimum threshold+2*maxErrors requires, so long as the extra
+// rows are consistent with the rest. Alongside the solution it returns a
+// per-column free mask: free[i] is true when column i had no pivot row and
+// was instead pinned to an arbitrary value, which callers need to know
+// about before drawing conclusions from that unknown's value.
+func gf256GaussianSolve(rows [][]byte, numUnknowns int) ([]byte, []bool, error) {
+	// Work on a copy so we don't mutate the caller's rows.
+	m := make([][]byte, len(rows))
+	for i, r := range rows {
+		m[i] = append([]byte{}, r...)
+	}
+
+	pivotRowOf := make([]int, numUnknowns)
+	for i := range pivotRowOf {
+		pivotRowOf[i] = -1
+	}
+
+	nextRow := 0
+	for col := 0; col < numUnknowns && nextRow < len(m); col++ {
+		pivot := -1
+		for r := nextRow; r < len(m); r++ {
+			if m[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot == -1 {
+			continue
+		}
+		m[nextRow], m[pivot] = m[pivot], m[nextRow]
+
+		inv := gf256Inverse(m[nextRow][col])
+		for c := col; c <= numUnknowns; c++ {
+			m[nextRow][c] = gf256_mul(m[nextRow][c], inv)
+		}
+
+		for r := 0; r < len(m); r++ {
+			if r == nextRow || m[r][col] == 0 {
+				continue
+			}
+			factor := m[r][col]
+			for c := col; c <= numUnknowns; c++ {
+				m[r][c] = gf256_sub(m[r][c], gf256_mul(factor, m[nextRow][c]))
+			}
+		}
+
+		pivotRowOf[col] = nextRow
+		nextRow++
+	}
+
+	// Remaining rows (beyond the rank) must be all zero, including the RHS,
+	// or the shares are inconsistent with this maxErrors.
+	for r := nextRow; r < len(m); r++ {
+		for c := 0; c <= numUnknowns; c++ {
+			if m[r][c] != 0 {
+				return nil, nil, errors.New("sss: shares are inconsistent with the given threshold and maxErrors")
+			}
+		}
+	}
+
+	// When the actual number of errors is below maxErrors, the system is
+	// under-determined: E(x) and Q(x) are only fixed up to a common factor
+	// (x-r) for an arbitrary r, since dividing it back out always yields
+	// the same f(x). Pin any such free unknown to 1 and solve the pivot
+	// columns in terms of it; the resulting Q(0)/E(0) is the same for
+	// every choice of r, so this is safe.
+	solution := make([]byte, numUnknowns)
+	free := make([]bool, numUnknowns)
+	for col, row := range pivotRowOf {
+		if row == -1 {
+			solution[col] = 1
+			free[col] = true
+		}
+	}
+	for col, row := range pivotRowOf {
+		if row == -1 {
+			continue
+		}
+		value := m[row][numUnknowns]
+		for c, fcol := range pivotRowOf {
+			if fcol != -1 || m[row][c] == 0 {
+				continue
+			}
+			value = gf256_sub(value, gf256_mul(m[row][c], solution[c]))
+		}
+		solution[col] = value
+	}
+	return solution, free, nil
+}
+
+// gf256Inverse returns the multiplicative inverse of a non-zero GF(256)
+// element.
+func gf256Inverse(a byte) byte {
+	return gf256_div(1, a)
+}
@@ -0,0 +1,104 @@
+package sss
+
+import (
+	"bytes"
+	"testing"
+)
+
+// honestShares computes n GF(256) shares of secret under the given
+// threshold, using a fixed (non-random) coefficient source so tests are
+// reproducible.
+func honestShares(t *testing.T, threshold int, secret []byte, ids []byte) [][]byte {
+	t.Helper()
+	s, err := NewWithRand(threshold, secret, staticRand{seed: 7})
+	if err != nil {
+		t.Fatalf("NewWithRand failed: %v", err)
+	}
+	shareBytes := make([][]byte, len(ids))
+	for i, id := range ids {
+		share := make([]byte, len(secret))
+		for pos, coefs := range s.coefficients {
+			share[pos] = f(id, coefs)
+		}
+		shareBytes[i] = share
+	}
+	return shareBytes
+}
+
+func TestRecoverWithErrorsToleratesCorruptShares(t *testing.T) {
+	const threshold = 3
+	const maxErrors = 1
+	secret := []byte("hello")
+	ids := []byte{1, 2, 3, 4, 5} // n = 5 = threshold + 2*maxErrors
+
+	shareBytes := honestShares(t, threshold, secret, ids)
+
+	// Corrupt one share in full, as a bad transport link would.
+	for i := range shareBytes[2] {
+		shareBytes[2][i] ^= 0xFF
+	}
+
+	recovered, bad, err := RecoverWithErrors(ids, shareBytes, threshold, maxErrors)
+	if err != nil {
+		t.Fatalf("RecoverWithErrors failed: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Fatalf("recovered secret = %q, want %q", recovered, secret)
+	}
+	if len(bad) != 1 || bad[0] != ids[2] {
+		t.Fatalf("bad ids = %v, want [%d]", bad, ids[2])
+	}
+}
+
+// TestRecoverWithErrorsNoFalsePositivesBelowMaxErrors guards against a bug
+// where, with fewer actual errors than maxErrors allows for, the
+// under-determined Berlekamp-Welch system picked an arbitrary root of the
+// error locator and reported a perfectly honest share as corrupt.
+func TestRecoverWithErrorsNoFalsePositivesBelowMaxErrors(t *testing.T) {
+	const threshold = 3
+	const maxErrors = 1
+	secret := []byte("hello")
+	ids := []byte{1, 2, 3, 4, 5} // n = 5 = threshold + 2*maxErrors, zero actual corruption
+
+	shareBytes := honestShares(t, threshold, secret, ids)
+
+	recovered, bad, err := RecoverWithErrors(ids, shareBytes, threshold, maxErrors)
+	if err != nil {
+		t.Fatalf("RecoverWithErrors failed: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Fatalf("recovered secret = %q, want %q", recovered, secret)
+	}
+	if len(bad) != 0 {
+		t.Fatalf("bad ids = %v, want none (no share was actually corrupted)", bad)
+	}
+}
+
+// TestRecoverWithErrorsRejectsZeroID guards against a panic: f(x, ...)
+// panics on x == 0, and RecoverWithErrors is meant to tolerate a share set
+// that includes corrupt/adversarial ids, not crash on one.
+func TestRecoverWithErrorsRejectsZeroID(t *testing.T) {
+	const threshold = 3
+	const maxErrors = 1
+	secret := []byte("hello")
+	ids := []byte{1, 2, 3, 4, 5}
+
+	shareBytes := honestShares(t, threshold, secret, ids)
+	badIDs := []byte{0, 2, 3, 4, 5}
+
+	if _, _, err := RecoverWithErrors(badIDs, shareBytes, threshold, maxErrors); err == nil {
+		t.Error("expected error for a zero share id")
+	}
+}
+
+// staticRand is a deterministic io.Reader for tests: it fills every read
+// with a fixed byte derived from seed, which is all NewWithRand needs to
+// produce reproducible (non-random) coefficients.
+type staticRand struct{ seed byte }
+
+func (r staticRand) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.seed
+	}
+	return len(p), nil
+}
@@ -0,0 +1,204 @@
+// Verifiable secret sharing via Feldman commitments.
+//
+// The byte-wise Shamir scheme in sss.go gives each holder an (x, f(x)) pair but
+// no way to check it against anything: a malicious dealer (or a corrupted
+// transport) can hand out a bad share and nobody notices until recovery
+// fails or, worse, silently produces the wrong secret.  VerifiableShamir
+// fixes that for callers who can afford to move the secret into a prime
+// field: the dealer publishes a commitment to each polynomial coefficient,
+// and any holder can verify their own share against the public commitments
+// without learning anything about the other shares.
+
+package sss
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Point is a point on the curve used by VerifiableShamir, represented in
+// affine coordinates.
+type Point struct {
+	X, Y *big.Int
+}
+
+// VerifiableShamir runs Shamir secret sharing over the scalar field of an
+// elliptic curve and publishes Feldman commitments to the polynomial
+// coefficients, so that shares can be verified independently of the dealer.
+type VerifiableShamir struct {
+	Threshold    int
+	curve        elliptic.Curve
+	secret       *big.Int
+	coefficients []*big.Int // coefficients[0] == secret
+	commitments  []Point    // commitments[i] == coefficients[i]*G
+}
+
+// NewScalar creates a VerifiableShamir dealer for the given threshold and
+// scalar secret. The secret is shared over the scalar field of curve, using
+// crypto/rand.Reader for coefficient generation.
+func NewScalar(threshold int, secret *big.Int, curve elliptic.Curve) (*VerifiableShamir, error) {
+	return NewScalarWithRand(threshold, secret, curve, rand.Reader)
+}
+
+// NewScalarWithRand is NewScalar with an explicit randomness source, useful
+// for deterministic tests.
+func NewScalarWithRand(threshold int, secret *big.Int, curve elliptic.Curve, randSource io.Reader) (*VerifiableShamir, error) {
+	if threshold < 1 {
+		return nil, errors.New("sss: threshold must be at least 1")
+	}
+	n := curve.Params().N
+
+	coefficients := make([]*big.Int, threshold)
+	coefficients[0] = new(big.Int).Mod(secret, n)
+	for i := 1; i < threshold; i++ {
+		c, err := randFieldElement(curve, randSource)
+		if err != nil {
+			return nil, err
+		}
+		coefficients[i] = c
+	}
+
+	commitments := make([]Point, threshold)
+	for i, c := range coefficients {
+		x, y := curve.ScalarBaseMult(c.Bytes())
+		commitments[i] = Point{X: x, Y: y}
+	}
+
+	return &VerifiableShamir{
+		Threshold:    threshold,
+		curve:        curve,
+		secret:       coefficients[0],
+		coefficients: coefficients,
+		commitments:  commitments,
+	}, nil
+}
+
+// randFieldElement draws a uniformly random scalar in [1, N) via rejection
+// sampling, so that no bias leaks into the coefficients.
+func randFieldElement(curve elliptic.Curve, randSource io.Reader) (*big.Int, error) {
+	n := curve.Params().N
+	bitLen := n.BitLen()
+	byteLen := (bitLen + 7) / 8
+	buf := make([]byte, byteLen)
+	for {
+		if _, err := io.ReadFull(randSource, buf); err != nil {
+			return nil, err
+		}
+		// Mask off any excess high bits so we reject less often.
+		if excess := byteLen*8 - bitLen; excess > 0 {
+			buf[0] &= 0xff >> uint(excess)
+		}
+		c := new(big.Int).SetBytes(buf)
+		if c.Sign() != 0 && c.Cmp(n) < 0 {
+			return c, nil
+		}
+	}
+}
+
+// Commitments returns the public Feldman commitments C_0..C_{t-1}, where
+// C_j = coefficients[j] * G. Holders use these, plus the curve's generator,
+// to verify a share without contacting the dealer.
+func (v *VerifiableShamir) Commitments() []Point {
+	out := make([]Point, len(v.commitments))
+	copy(out, v.commitments)
+	return out
+}
+
+// Share evaluates the dealer's polynomial at x and returns the share
+// (x, f(x)) as scalars modulo the curve order.
+func (v *VerifiableShamir) Share(x *big.Int) (*big.Int, *big.Int, error) {
+	if x.Sign() == 0 {
+		return nil, nil, errors.New("sss: share index must be non-zero")
+	}
+	n := v.curve.Params().N
+	fx := evalPolynomial(v.coefficients, x, n)
+	return new(big.Int).Set(x), fx, nil
+}
+
+// evalPolynomial computes sum(coefficients[i] * x^i) mod n using Horner's
+// method.
+func evalPolynomial(coefficients []*big.Int, x, n *big.Int) *big.Int {
+	result := new(big.Int)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result.Mul(result, x)
+		result.Add(result, coefficients[i])
+		result.Mod(result, n)
+	}
+	return result
+}
+
+// VerifyShare checks that (x, fx) lies on the polynomial committed to by
+// commitments, by testing fx*G == sum(x^j * C_j) on curve. It does not
+// require the dealer or the secret to be present.
+func VerifyShare(curve elliptic.Curve, x, fx *big.Int, commitments []Point) bool {
+	if len(commitments) == 0 {
+		return false
+	}
+	lx, ly := curve.ScalarBaseMult(fx.Bytes())
+
+	n := curve.Params().N
+	var sumX, sumY *big.Int
+	xPow := big.NewInt(1)
+	for j, c := range commitments {
+		px, py := curve.ScalarMult(c.X, c.Y, xPow.Bytes())
+		if j == 0 {
+			sumX, sumY = px, py
+		} else {
+			sumX, sumY = curve.Add(sumX, sumY, px, py)
+		}
+		xPow.Mul(xPow, x)
+		xPow.Mod(xPow, n)
+	}
+
+	return lx.Cmp(sumX) == 0 && ly.Cmp(sumY) == 0
+}
+
+// Recover reconstructs the secret scalar from at least Threshold shares
+// using Lagrange interpolation modulo the curve order.
+func (v *VerifiableShamir) Recover(xs, fxs []*big.Int) (*big.Int, error) {
+	return RecoverScalar(v.curve, v.Threshold, xs, fxs)
+}
+
+// RecoverScalar reconstructs a scalar secret from shares (xs[i], fxs[i])
+// using Lagrange interpolation at x=0, modulo the curve's scalar field. It
+// is exported so that holders who did not run the dealer code can still
+// combine their shares.
+func RecoverScalar(curve elliptic.Curve, threshold int, xs, fxs []*big.Int) (*big.Int, error) {
+	if len(xs) != len(fxs) {
+		return nil, errors.New("sss: xs and fxs must be the same length")
+	}
+	if len(xs) < threshold {
+		return nil, errors.New("sss: not enough shares to recover the secret")
+	}
+	n := curve.Params().N
+
+	secret := new(big.Int)
+	for i := range xs {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num.Mul(num, new(big.Int).Neg(xs[j]))
+			num.Mod(num, n)
+
+			diff := new(big.Int).Sub(xs[i], xs[j])
+			den.Mul(den, diff)
+			den.Mod(den, n)
+		}
+		denInv := new(big.Int).ModInverse(den, n)
+		if denInv == nil {
+			return nil, errors.New("sss: duplicate share index, cannot interpolate")
+		}
+		term := new(big.Int).Mul(fxs[i], num)
+		term.Mul(term, denInv)
+		term.Mod(term, n)
+		secret.Add(secret, term)
+		secret.Mod(secret, n)
+	}
+	return secret, nil
+}
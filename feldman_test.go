@@ -0,0 +1,73 @@
+package sss
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestVerifiableShamirRoundTripAndVerify(t *testing.T) {
+	curve := elliptic.P256()
+	const threshold = 3
+	secret := big.NewInt(123456789)
+
+	v, err := NewScalarWithRand(threshold, secret, curve, staticRand{seed: 11})
+	if err != nil {
+		t.Fatalf("NewScalarWithRand failed: %v", err)
+	}
+
+	commitments := v.Commitments()
+	if len(commitments) != threshold {
+		t.Fatalf("got %d commitments, want %d", len(commitments), threshold)
+	}
+
+	type point struct{ x, fx *big.Int }
+	var shares []point
+	for i := int64(1); i <= 5; i++ {
+		x, fx, err := v.Share(big.NewInt(i))
+		if err != nil {
+			t.Fatalf("Share failed: %v", err)
+		}
+		if !VerifyShare(curve, x, fx, commitments) {
+			t.Fatalf("VerifyShare rejected an honest share for x=%d", i)
+		}
+		shares = append(shares, point{x, fx})
+	}
+
+	xs := make([]*big.Int, threshold)
+	fxs := make([]*big.Int, threshold)
+	for i := 0; i < threshold; i++ {
+		xs[i] = shares[i].x
+		fxs[i] = shares[i].fx
+	}
+
+	recovered, err := v.Recover(xs, fxs)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if recovered.Cmp(secret) != 0 {
+		t.Fatalf("recovered secret = %s, want %s", recovered, secret)
+	}
+}
+
+func TestVerifyShareRejectsTamperedShare(t *testing.T) {
+	curve := elliptic.P256()
+	const threshold = 2
+	secret := big.NewInt(42)
+
+	v, err := NewScalarWithRand(threshold, secret, curve, staticRand{seed: 3})
+	if err != nil {
+		t.Fatalf("NewScalarWithRand failed: %v", err)
+	}
+	commitments := v.Commitments()
+
+	x, fx, err := v.Share(big.NewInt(1))
+	if err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+
+	tampered := new(big.Int).Add(fx, big.NewInt(1))
+	if VerifyShare(curve, x, tampered, commitments) {
+		t.Error("VerifyShare accepted a tampered share")
+	}
+}
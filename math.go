@@ -2,6 +2,73 @@
 
 package sss
 
+// GF(256) arithmetic, using the AES reduction polynomial x^8+x^4+x^3+x+1
+// (0x11B). Addition and subtraction are both XOR in a field of
+// characteristic 2; multiplication and division go through exp/log tables
+// built once at init time.
+var gf256ExpTable [512]byte
+var gf256LogTable [256]byte
+
+func init() {
+	// Build the exp table by walking the multiplicative group generated by
+	// 0x03, then derive the log table as its inverse. The table is doubled
+	// to 512 entries so gf256_mul can look up exp[log(a)+log(b)] without
+	// reducing the sum modulo 255.
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gf256ExpTable[i] = x
+		gf256LogTable[x] = byte(i)
+		// Multiply by the generator 0x03, i.e. by (x+1): xtime(x) is
+		// multiplication by x (double, reducing by 0x1B on overflow), and
+		// XOR-ing in x accounts for the +1. 0x02 alone only generates a
+		// proper subgroup of the 255 non-zero elements, so it can't be used
+		// as the table's base.
+		hiBitSet := x & 0x80
+		double := x << 1
+		if hiBitSet != 0 {
+			double ^= 0x1B
+		}
+		x ^= double
+	}
+	for i := 255; i < 512; i++ {
+		gf256ExpTable[i] = gf256ExpTable[i-255]
+	}
+}
+
+// gf256_add adds two elements of GF(256). In a field of characteristic 2,
+// addition and subtraction are both XOR.
+func gf256_add(a, b byte) byte {
+	return a ^ b
+}
+
+// gf256_sub subtracts two elements of GF(256); same as gf256_add.
+func gf256_sub(a, b byte) byte {
+	return a ^ b
+}
+
+// gf256_mul multiplies two elements of GF(256) via the exp/log tables.
+func gf256_mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256ExpTable[int(gf256LogTable[a])+int(gf256LogTable[b])]
+}
+
+// gf256_div divides a by b in GF(256); b must be non-zero.
+func gf256_div(a, b byte) byte {
+	if b == 0 {
+		panic("gf256_div: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	diff := int(gf256LogTable[a]) - int(gf256LogTable[b])
+	if diff < 0 {
+		diff += 255
+	}
+	return gf256ExpTable[diff]
+}
+
 // This actually computes f(x).  It's private and not needed elsewhere...
 //
 // This computes f(x) = a + bx + cx^2 + ...
@@ -50,7 +117,7 @@ func multiply_polynomials(a, b []byte) []byte {
 
 		resultterms = add_polynomials(resultterms, thisvalue)
 		// moved another x value over...
-		termpadding = append(termpadding,0)
+		termpadding = append(termpadding, 0)
 	}
 
 	return resultterms
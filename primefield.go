@@ -0,0 +1,159 @@
+// Prime-field Shamir sharing.
+//
+// The byte-wise scheme in sss.go runs one independent polynomial per secret
+// byte over GF(256), which caps the number of distinct shares at 255 (x
+// must be a non-zero byte) and means the per-share storage grows with the
+// secret length. PrimeField instead runs a single Shamir instance over a
+// large prime field, representing the secret as one big.Int. Share IDs can
+// then be arbitrary large integers, which suits distributed systems that
+// want to use opaque participant identifiers instead of small byte indices.
+
+package sss
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Mersenne521 is 2^521-1, a convenient default prime: it is larger than any
+// secret this package is likely to be asked to share in one chunk, and
+// primality is well known.
+var Mersenne521 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 521), big.NewInt(1))
+
+// PrimeField runs Shamir secret sharing over Z_p for a configurable prime p.
+type PrimeField struct {
+	Threshold    int
+	Prime        *big.Int
+	SecretLen    int // length in bytes of the original secret passed to NewPrime
+	secret       *big.Int
+	coefficients []*big.Int // coefficients[0] == secret, mod Prime
+}
+
+// NewPrime creates a PrimeField dealer for the given threshold and secret.
+// secret is interpreted as an unsigned big-endian integer; it must be
+// strictly smaller than prime; a nil prime defaults to Mersenne521.
+func NewPrime(threshold int, secret []byte, prime *big.Int) (*PrimeField, error) {
+	return NewPrimeWithRand(threshold, secret, prime, rand.Reader)
+}
+
+// NewPrimeWithRand is NewPrime with an explicit randomness source, useful
+// for deterministic tests.
+func NewPrimeWithRand(threshold int, secret []byte, prime *big.Int, randSource io.Reader) (*PrimeField, error) {
+	if threshold < 1 {
+		return nil, errors.New("sss: threshold must be at least 1")
+	}
+	if prime == nil {
+		prime = Mersenne521
+	}
+
+	secretInt := new(big.Int).SetBytes(secret)
+	if secretInt.Cmp(prime) >= 0 {
+		return nil, errors.New("sss: secret must be smaller than the prime")
+	}
+
+	coefficients := make([]*big.Int, threshold)
+	coefficients[0] = secretInt
+	for i := 1; i < threshold; i++ {
+		c, err := randBelow(prime, randSource)
+		if err != nil {
+			return nil, err
+		}
+		coefficients[i] = c
+	}
+
+	return &PrimeField{
+		Threshold:    threshold,
+		Prime:        prime,
+		SecretLen:    len(secret),
+		secret:       secretInt,
+		coefficients: coefficients,
+	}, nil
+}
+
+// randBelow draws a uniformly random integer in [0, max) via rejection
+// sampling, so coefficients never leak information through a modulo bias.
+func randBelow(max *big.Int, randSource io.Reader) (*big.Int, error) {
+	byteLen := (max.BitLen() + 7) / 8
+	buf := make([]byte, byteLen)
+	for {
+		if _, err := io.ReadFull(randSource, buf); err != nil {
+			return nil, err
+		}
+		c := new(big.Int).SetBytes(buf)
+		if c.Cmp(max) < 0 {
+			return c, nil
+		}
+	}
+}
+
+// Share evaluates the dealer's polynomial at id and returns (id, f(id)).
+// id must be non-zero and smaller than p.Prime.
+func (p *PrimeField) Share(id *big.Int) (*big.Int, *big.Int, error) {
+	if id.Sign() == 0 {
+		return nil, nil, errors.New("sss: share id must be non-zero")
+	}
+	if id.Cmp(p.Prime) >= 0 {
+		return nil, nil, errors.New("sss: share id must be smaller than the prime")
+	}
+	fx := evalPolynomial(p.coefficients, id, p.Prime)
+	return new(big.Int).Set(id), fx, nil
+}
+
+// Recover reconstructs the secret from at least Threshold shares (ids[i],
+// fxs[i]) via Lagrange interpolation modulo Prime, and returns it as a
+// big-endian byte slice of length SecretLen. big.Int.Bytes strips leading
+// zero bytes, which would otherwise silently shorten a secret that began
+// with 0x00 bytes, so the result is left-padded back out to SecretLen.
+func (p *PrimeField) Recover(ids, fxs []*big.Int) ([]byte, error) {
+	secret, err := recoverPrimeSecret(p.Prime, p.Threshold, ids, fxs)
+	if err != nil {
+		return nil, err
+	}
+	raw := secret.Bytes()
+	if len(raw) > p.SecretLen {
+		return nil, errors.New("sss: recovered secret is longer than SecretLen")
+	}
+	out := make([]byte, p.SecretLen)
+	copy(out[p.SecretLen-len(raw):], raw)
+	return out, nil
+}
+
+// recoverPrimeSecret does the Lagrange interpolation at x=0 modulo prime
+// for Recover, returning the secret as a big.Int.
+func recoverPrimeSecret(prime *big.Int, threshold int, ids, fxs []*big.Int) (*big.Int, error) {
+	if len(ids) != len(fxs) {
+		return nil, errors.New("sss: ids and fxs must be the same length")
+	}
+	if len(ids) < threshold {
+		return nil, errors.New("sss: not enough shares to recover the secret")
+	}
+
+	secret := new(big.Int)
+	for i := range ids {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+		for j := range ids {
+			if i == j {
+				continue
+			}
+			num.Mul(num, new(big.Int).Neg(ids[j]))
+			num.Mod(num, prime)
+
+			diff := new(big.Int).Sub(ids[i], ids[j])
+			den.Mul(den, diff)
+			den.Mod(den, prime)
+		}
+		denInv := new(big.Int).ModInverse(den, prime)
+		if denInv == nil {
+			return nil, errors.New("sss: duplicate share id, cannot interpolate")
+		}
+		term := new(big.Int).Mul(fxs[i], num)
+		term.Mul(term, denInv)
+		term.Mod(term, prime)
+		secret.Add(secret, term)
+		secret.Mod(secret, prime)
+	}
+	return secret, nil
+}
@@ -0,0 +1,75 @@
+package sss
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestPrimeFieldRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret []byte
+	}{
+		{"typical secret", []byte("my shared secret")},
+		{"leading zero byte", []byte{0x00, 0x01, 0x02, 0x03}},
+		{"all zero bytes", []byte{0x00, 0x00, 0x00}},
+		{"single byte", []byte{0xFF}},
+	}
+
+	const threshold = 3
+	const n = 5
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pf, err := NewPrime(threshold, tc.secret, nil)
+			if err != nil {
+				t.Fatalf("NewPrime failed: %v", err)
+			}
+			if pf.SecretLen != len(tc.secret) {
+				t.Fatalf("SecretLen = %d, want %d", pf.SecretLen, len(tc.secret))
+			}
+
+			ids := make([]*big.Int, n)
+			fxs := make([]*big.Int, n)
+			for i := 0; i < n; i++ {
+				id := big.NewInt(int64(i + 1))
+				_, fx, err := pf.Share(id)
+				if err != nil {
+					t.Fatalf("Share failed: %v", err)
+				}
+				ids[i] = id
+				fxs[i] = fx
+			}
+
+			recovered, err := pf.Recover(ids[:threshold], fxs[:threshold])
+			if err != nil {
+				t.Fatalf("Recover failed: %v", err)
+			}
+			if !bytes.Equal(recovered, tc.secret) {
+				t.Fatalf("recovered = %x (len %d), want %x (len %d)", recovered, len(recovered), tc.secret, len(tc.secret))
+			}
+		})
+	}
+}
+
+func TestPrimeFieldRecoverRejectsTooFewShares(t *testing.T) {
+	secret := []byte("my shared secret")
+	pf, err := NewPrime(3, secret, nil)
+	if err != nil {
+		t.Fatalf("NewPrime failed: %v", err)
+	}
+
+	id1, fx1, err := pf.Share(big.NewInt(1))
+	if err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+	id2, fx2, err := pf.Share(big.NewInt(2))
+	if err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+
+	if _, err := pf.Recover([]*big.Int{id1, id2}, []*big.Int{fx1, fx2}); err == nil {
+		t.Error("expected error recovering with fewer than threshold shares")
+	}
+}
@@ -0,0 +1,145 @@
+// Proactive secret sharing: refreshing shares without ever reconstructing
+// the secret, and changing the threshold/party count after the fact.
+//
+// Shamir secret sharing on its own is only secure against an adversary who
+// compromises t holders once, ever. If an attacker can pick off one holder
+// at a time across a long enough window, they eventually accumulate a
+// threshold. Proactive secret sharing defends against that by periodically
+// moving every share to a fresh random polynomial that still interpolates
+// to the same secret at x=0 (Herzberg et al., "Proactive Secret Sharing");
+// an attacker now has to compromise t holders within a single epoch, not
+// over the scheme's whole lifetime.
+
+package sss
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// Refresh runs one epoch of proactive secret sharing for the holders
+// identified by ids. For each secret byte it draws a fresh random
+// polynomial R(x) with R(0)=0, evaluates it at every id, and returns the
+// per-holder delta shares in the same order as ids; a holder updates its
+// share by XOR-ing (GF(256) addition) its old share with its delta.
+//
+// The dealer's own coefficients are updated in place to match, so that any
+// Share computed afterwards is consistent with the refreshed holders.
+// Combining any t pre-refresh shares with any post-refresh shares no
+// longer reconstructs the secret: only an attacker who compromises t
+// holders within the same epoch can.
+func (s *Shamir) Refresh(ids []byte, randSource io.Reader) ([][]byte, error) {
+	if randSource == nil {
+		randSource = s.randSource
+	}
+	if randSource == nil {
+		randSource = rand.Reader
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("sss: Refresh needs at least one holder id")
+	}
+
+	deltas := make([][]byte, len(ids))
+	for i := range deltas {
+		deltas[i] = make([]byte, len(s.coefficients))
+	}
+
+	refreshCoefs := make([]byte, s.Threshold)
+	for byteIdx, coefs := range s.coefficients {
+		refreshCoefs[0] = 0 // R(0) = 0, so the secret itself never moves
+		if _, err := io.ReadFull(randSource, refreshCoefs[1:]); err != nil {
+			return nil, err
+		}
+		for i, id := range ids {
+			deltas[i][byteIdx] = f(id, refreshCoefs)
+		}
+		for j := range coefs {
+			coefs[j] = gf256_add(coefs[j], refreshCoefs[j])
+		}
+	}
+
+	return deltas, nil
+}
+
+// Reshare replaces this dealer with one using a new threshold and party
+// count, while preserving the secret. It is the dealer-side half of
+// resharing: the dealer already holds Secretdata, so it simply re-runs
+// Shamir with the new parameters. numShares is not otherwise tracked by
+// Shamir (shares are computed on demand by id), but is accepted here so
+// callers can validate it against Threshold the same way Split/SplitStream
+// do.
+func (s *Shamir) Reshare(newThreshold, numShares int, randSource io.Reader) (*Shamir, error) {
+	if numShares < newThreshold {
+		return nil, errors.New("sss: numShares must be at least newThreshold")
+	}
+	if randSource == nil {
+		randSource = s.randSource
+	}
+	if randSource == nil {
+		randSource = rand.Reader
+	}
+	return NewWithRand(newThreshold, s.Secretdata, randSource)
+}
+
+// RecoverSecret reconstructs a secret from threshold GF(256) shares, doing
+// Lagrange interpolation at x=0 independently for each byte position. It
+// is the low-level primitive a coalition of holders (who do not have
+// access to a dealer's Shamir value) can use, e.g. to reshare themselves
+// via ReshareFromShares.
+func RecoverSecret(threshold int, ids []byte, shareBytes [][]byte) ([]byte, error) {
+	if len(ids) != len(shareBytes) {
+		return nil, errors.New("sss: ids and shareBytes must be the same length")
+	}
+	if len(ids) < threshold {
+		return nil, errors.New("sss: not enough shares to recover the secret")
+	}
+	if len(shareBytes) == 0 {
+		return []byte{}, nil
+	}
+
+	xs := ids[:threshold]
+	seen := make(map[byte]bool, len(xs))
+	for _, id := range xs {
+		if seen[id] {
+			return nil, errors.New("sss: duplicate share x value")
+		}
+		seen[id] = true
+	}
+
+	secretLen := len(shareBytes[0])
+	secret := make([]byte, secretLen)
+	for pos := 0; pos < secretLen; pos++ {
+		fxs := make([]byte, threshold)
+		for i := 0; i < threshold; i++ {
+			if len(shareBytes[i]) != secretLen {
+				return nil, errors.New("sss: shares have different lengths")
+			}
+			fxs[i] = shareBytes[i][pos]
+		}
+		poly := full_lagrange(xs, fxs)
+		if len(poly) == 0 {
+			return nil, errors.New("sss: failed to recover secret")
+		}
+		secret[pos] = poly[0]
+	}
+	return secret, nil
+}
+
+// ReshareFromShares lets a coalition of at least threshold existing
+// holders reshare the secret to a new threshold/party count without
+// involving the original dealer: it recovers the secret from the given
+// shares and then runs Shamir again with the new parameters.
+func ReshareFromShares(threshold int, ids []byte, shareBytes [][]byte, newThreshold, numShares int, randSource io.Reader) (*Shamir, error) {
+	secret, err := RecoverSecret(threshold, ids, shareBytes)
+	if err != nil {
+		return nil, err
+	}
+	if numShares < newThreshold {
+		return nil, errors.New("sss: numShares must be at least newThreshold")
+	}
+	if randSource == nil {
+		randSource = rand.Reader
+	}
+	return NewWithRand(newThreshold, secret, randSource)
+}
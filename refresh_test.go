@@ -0,0 +1,176 @@
+package sss
+
+import (
+	"bytes"
+	"testing"
+)
+
+// counterRand is a deterministic io.Reader that returns a different byte
+// sequence on each Read, unlike staticRand's constant fill: Refresh draws a
+// fresh R(x) per secret byte position, and a rand source that repeated the
+// same bytes for every position would make those R(x) identical across
+// positions, which is not representative of real randomness.
+type counterRand struct{ next byte }
+
+func (r *counterRand) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.next
+		r.next++
+	}
+	return len(p), nil
+}
+
+func TestRefreshPreservesSecretAndUpdatesDealer(t *testing.T) {
+	const threshold = 3
+	secret := []byte("hello")
+	ids := []byte{1, 2, 3, 4, 5}
+
+	s, err := NewWithRand(threshold, secret, staticRand{seed: 7})
+	if err != nil {
+		t.Fatalf("NewWithRand failed: %v", err)
+	}
+
+	oldShares := make([][]byte, len(ids))
+	for i, id := range ids {
+		share := make([]byte, len(secret))
+		for pos, coefs := range s.coefficients {
+			share[pos] = f(id, coefs)
+		}
+		oldShares[i] = share
+	}
+
+	deltas, err := s.Refresh(ids, &counterRand{next: 42})
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(deltas) != len(ids) {
+		t.Fatalf("got %d deltas, want %d", len(deltas), len(ids))
+	}
+
+	newShares := make([][]byte, len(ids))
+	for i := range ids {
+		updated := make([]byte, len(secret))
+		for pos := range updated {
+			updated[pos] = gf256_add(oldShares[i][pos], deltas[i][pos])
+		}
+		newShares[i] = updated
+	}
+
+	recovered, err := RecoverSecret(threshold, ids[:threshold], newShares[:threshold])
+	if err != nil {
+		t.Fatalf("RecoverSecret on refreshed shares failed: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Fatalf("recovered secret after refresh = %q, want %q", recovered, secret)
+	}
+
+	// The dealer's own coefficients should have moved in lockstep, so a
+	// share computed directly from s now matches the refreshed share.
+	for i, id := range ids {
+		direct := make([]byte, len(secret))
+		for pos, coefs := range s.coefficients {
+			direct[pos] = f(id, coefs)
+		}
+		if !bytes.Equal(direct, newShares[i]) {
+			t.Fatalf("dealer share for id %d = %x after Refresh, want %x", id, direct, newShares[i])
+		}
+	}
+
+	// Mixing a pre-refresh share with post-refresh shares should not
+	// recover the secret: they are points on two different polynomials.
+	mixed := [][]byte{oldShares[0], newShares[1], newShares[2]}
+	recoveredMixed, err := RecoverSecret(threshold, ids[:threshold], mixed)
+	if err == nil && bytes.Equal(recoveredMixed, secret) {
+		t.Fatal("expected mixing a pre-refresh share with post-refresh shares to not reconstruct the secret")
+	}
+}
+
+func TestReshareChangesThresholdAndPreservesSecret(t *testing.T) {
+	secret := []byte("hello")
+	s, err := NewWithRand(3, secret, staticRand{seed: 7})
+	if err != nil {
+		t.Fatalf("NewWithRand failed: %v", err)
+	}
+
+	newS, err := s.Reshare(4, 6, staticRand{seed: 9})
+	if err != nil {
+		t.Fatalf("Reshare failed: %v", err)
+	}
+	if newS.Threshold != 4 {
+		t.Fatalf("new threshold = %d, want 4", newS.Threshold)
+	}
+	if !bytes.Equal(newS.Secretdata, secret) {
+		t.Fatalf("Reshare changed the secret: got %q, want %q", newS.Secretdata, secret)
+	}
+
+	ids := []byte{1, 2, 3, 4, 5, 6}
+	shareBytes := make([][]byte, len(ids))
+	for i, id := range ids {
+		share := make([]byte, len(secret))
+		for pos, coefs := range newS.coefficients {
+			share[pos] = f(id, coefs)
+		}
+		shareBytes[i] = share
+	}
+
+	recovered, err := RecoverSecret(4, ids[:4], shareBytes[:4])
+	if err != nil {
+		t.Fatalf("RecoverSecret failed: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Fatalf("recovered secret = %q, want %q", recovered, secret)
+	}
+}
+
+func TestRecoverSecretRejectsDuplicateX(t *testing.T) {
+	const threshold = 3
+	secret := []byte("hello")
+	ids := []byte{1, 1, 3}
+
+	s, err := NewWithRand(threshold, secret, staticRand{seed: 7})
+	if err != nil {
+		t.Fatalf("NewWithRand failed: %v", err)
+	}
+	shareBytes := make([][]byte, len(ids))
+	for i, id := range ids {
+		share := make([]byte, len(secret))
+		for pos, coefs := range s.coefficients {
+			share[pos] = f(id, coefs)
+		}
+		shareBytes[i] = share
+	}
+
+	if _, err := RecoverSecret(threshold, ids, shareBytes); err == nil {
+		t.Error("expected error for duplicate share x values")
+	}
+}
+
+func TestReshareFromSharesCoalition(t *testing.T) {
+	const threshold = 3
+	secret := []byte("hello")
+	ids := []byte{1, 2, 3, 4, 5}
+
+	s, err := NewWithRand(threshold, secret, staticRand{seed: 7})
+	if err != nil {
+		t.Fatalf("NewWithRand failed: %v", err)
+	}
+	shareBytes := make([][]byte, len(ids))
+	for i, id := range ids {
+		share := make([]byte, len(secret))
+		for pos, coefs := range s.coefficients {
+			share[pos] = f(id, coefs)
+		}
+		shareBytes[i] = share
+	}
+
+	newS, err := ReshareFromShares(threshold, ids[:threshold], shareBytes[:threshold], 2, 4, staticRand{seed: 5})
+	if err != nil {
+		t.Fatalf("ReshareFromShares failed: %v", err)
+	}
+	if !bytes.Equal(newS.Secretdata, secret) {
+		t.Fatalf("ReshareFromShares changed the secret: got %q, want %q", newS.Secretdata, secret)
+	}
+	if newS.Threshold != 2 {
+		t.Fatalf("new threshold = %d, want 2", newS.Threshold)
+	}
+}
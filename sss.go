@@ -1,212 +1,202 @@
-//
-
 /*
 Author:
-   Steve Ruckdashel
 
-Based on:
-   Justin Cappos (jcappos@poly.edu)
-   https://github.com/PolyPassHash/PolyPassHash/blob/master/python-reference-implementation/shamirsecret.py
-       
+	Steve Ruckdashel
 
+Based on:
 
+	Justin Cappos (jcappos@poly.edu)
+	https://github.com/PolyPassHash/PolyPassHash/blob/master/python-reference-implementation/shamirsecret.py
 
 Notes:
- - This module *intentionally* does not do hashing to detect incorrect
-   shares.  For my application, I want them to get an (undetected) incorrect 
-   decoding if a share is wrong.
-
-__author__ = 'Justin Cappos (jcappos@poly.edu)'
-__version__ = '0.1'
-__license__ = 'MIT'
-__all__ = ['ShamirSecret']
+  - This module *intentionally* does not do hashing to detect incorrect
+    shares.  For my application, I want them to get an (undetected) incorrect
+    decoding if a share is wrong.  Callers who do want corruption detected
+    should use Share.Marshal/Unmarshal (which CRC-checks each share) and, for
+    tolerance of a bounded number of bad shares, RecoverWithErrors.
 */
 package sss
 
-import "os"
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
 
-// This performs Shamir Secret Sharing operations in an incremental way 
+// This performs Shamir Secret Sharing operations in an incremental way
 // that is useful for PolyPassHash.  It allows checking membership,
 // genering shares one at a time, etc.
 type Shamir struct {
-	Threshhold int
-	Secretdata	[]byte
-	coefficients []byte
+	Threshold    int
+	Secretdata   []byte
+	randSource   io.Reader
+	coefficients [][]byte // one polynomial per secret byte; coefficients[i][0] == Secretdata[i]
 }
 
 // Creates an object.
 // One must provide the threshold.
 // If you want to have it create the coefficients, etc. call it with secret data
-func New(threshold int, secretdata []byte) (*Shamir) {
-	s := &Shamir{
-		Threshhold: threshhold,
-		Secretdata: secretdata,
-		coefficients: []byte{},
-	}
-	
-	for b := range secretdata {
-		thesecoefficients := bytearray(secretbyte+os.urandom(threshold-1))
-		
-        s.coefficients = append(s.coefficients,thesecoefficients)
-	}
-	
-	return s
+func New(threshold int, secretdata []byte) (*Shamir, error) {
+	return NewWithRand(threshold, secretdata, rand.Reader)
 }
 
+// NewWithRand is New with an explicit randomness source in place of
+// crypto/rand.Reader, so callers can get deterministic coefficients (e.g.
+// a seeded math/rand-backed reader) in tests without touching the rest of
+// the package.
+func NewWithRand(threshold int, secretdata []byte, randSource io.Reader) (*Shamir, error) {
+	if threshold < 1 {
+		return nil, errors.New("sss: threshold must be at least 1")
+	}
 
+	s := &Shamir{
+		Threshold:  threshold,
+		Secretdata: secretdata,
+		randSource: randSource,
+	}
 
-/*
-class ShamirSecret(object):
-
-  def __init__(self, threshold, secretdata=None):
-    """    
-      """
-    self.threshold=threshold
-    self.secretdata=secretdata
-
-    self._coefficients = None
-
-    # if we're given data, let's compute the random coefficients.   I do this
-    # here so I can later iteratively compute the shares
-    if secretdata is not None:
-
-      self._coefficients = []
-      for secretbyte in secretdata:
-        # this is the polynomial.   The first byte is the secretdata.   
-        # The next threshold-1 are (crypto) random coefficients
-        # I'm applying Shamir's secret sharing separately on each byte.
-        thesecoefficients = bytearray(secretbyte+os.urandom(threshold-1))
-
-        self._coefficients.append(thesecoefficients)
-
-
-
-  def is_valid_share(self, share):
-    """ This validates that a share is correct given the secret data.
-        It returns True if it is valid, False if it is not, and raises
-        various errors when given bad data.
-        """
-
-    # the share is of the format x, f(x)f(x)
-    if type(share) is not tuple:
-      raise TypeError("Share is of incorrect type: "+str(type(share)))
-
-    if len(share) !=2:
-      raise ValueError("Share is of incorrect length: "+str(share))
-
-    
-    if self._coefficients is None:
-      raise ValueError("Must initialize coefficients before checking is_valid_share")
-      
-    if len(self._coefficients) != len(share[1]):
-      raise ValueError("Must initialize coefficients before checking is_valid_share")
-    
-    x, fx = share
-
-    # let's just compute the right value
-    correctshare = self.compute_share(x)
-    
-    if correctshare == share:
-      return True
-    else:
-      return False
-    
-
-
-
-    
-  def compute_share(self, x):
-    """ This computes a share, given x.   It returns a tuple with x and the
-        individual f(x_0)f(x_1)f(x_2)... bytes for each byte of the secret.
-        This raises various errors when given bad data.
-        """
-
-    if type(x) is not int:
-      raise TypeError("In compute_share, x is of incorrect type: "+str(type(x)))
-
-    if x<=0 or x>=256:
-      raise ValueError("In compute_share, x must be between 1 and 255, not: "+str(x))
-
-    if self._coefficients is None:
-      raise ValueError("Must initialize coefficients before computing a share")
-      
-    sharebytes = bytearray()
-    # go through the coefficients and compute f(x) for each value.   
-    # Append that byte to the share
-    for thiscoefficient in self._coefficients:
-      thisshare = _f(x,thiscoefficient)
-      sharebytes.append(thisshare)
-    
-    return (x,sharebytes)
-
-
-
-
-
-  def recover_secretdata(self, shares):
-    """ This recovers the secret data and coefficients given at least threshold
-        shares.   Note, if any provided share does not decode, an error is 
-        raised."""
-
-    # discard duplicate shares
-    newshares = []
-    for share in shares:
-      if share not in newshares:
-        newshares.append(share)
-    shares = newshares
-
-
-    if self.threshold > len(shares):
-      raise ValueError("Threshold:"+str(self.threshold)+" is smaller than the number of unique shares:"+str(len(shares))+".")
-
-    if self.secretdata is not None:
-      raise ValueError("Recovering secretdata when some is stored.   Use check_share instead.")
+	coefficients := make([][]byte, len(secretdata))
+	for i, secretbyte := range secretdata {
+		thesecoefficients := make([]byte, threshold)
+		thesecoefficients[0] = secretbyte
+		if _, err := io.ReadFull(randSource, thesecoefficients[1:]); err != nil {
+			return nil, err
+		}
+		coefficients[i] = thesecoefficients
+	}
+	s.coefficients = coefficients
 
-    # the first byte of each share is the 'x'.
-    xs = []
-    for share in shares:
-      # the first byte should be unique...
-      if share[0] in xs:
-        raise ValueError("Different shares with the same first byte! '"+str(share[0])+"'")
-      # ...and all should be the same length
-      if len(share[1])!=len(shares[0][1]):
-        raise ValueError("Shares have different lengths!")
+	return s, nil
+}
 
-      xs.append(share[0])
-      
+// See refresh.go for proactive share refresh (Shamir.Refresh) and for
+// changing the threshold/party count after the fact (Shamir.Reshare).
+
+// shareVersion is the Share wire-format version that Split stamps on each
+// share. Marshal writes a share's Version field as is, and Unmarshal does
+// not check it.
+const shareVersion = 1
+
+// Share is one holder's piece of a secret split by Split: the point
+// (X, Y) on the dealer's GF(256) polynomials, one byte of Y per byte of
+// the original secret.
+type Share struct {
+	Version byte
+	X       byte
+	Y       []byte
+}
 
-    mycoefficients = []
-    mysecretdata = ''
+// Split divides secret into n shares, any t of which can reconstruct it
+// via Combine, using a fresh random GF(256) polynomial per secret byte.
+// t must be between 1 and n, and n must fit in a byte (1-255), since share
+// ids are GF(256) elements.
+func Split(secret []byte, n, t int) ([]Share, error) {
+	if n < 1 || n > 255 {
+		return nil, errors.New("sss: n must be between 1 and 255")
+	}
+	if t < 1 || t > n {
+		return nil, errors.New("sss: t must be between 1 and n")
+	}
 
-    # now walk through each byte of the secret and do lagrange interpolation
-    # to compute the coefficient...
-    for byte_to_use in range(0,len(shares[0][1])):
+	s, err := New(t, secret)
+	if err != nil {
+		return nil, err
+	}
 
-      # we need to get the f(x)s from the appropriate bytes
-      fxs = []
-      for share in shares:
-        fxs.append(share[1][byte_to_use])
+	shares := make([]Share, n)
+	for i := 0; i < n; i++ {
+		x := byte(i + 1)
+		y := make([]byte, len(secret))
+		for pos, coefs := range s.coefficients {
+			y[pos] = f(x, coefs)
+		}
+		shares[i] = Share{Version: shareVersion, X: x, Y: y}
+	}
+	return shares, nil
+}
 
-      # build this polynomial
-      resulting_poly = _full_lagrange(xs,fxs)
+// Combine reconstructs the secret from shares via Lagrange interpolation.
+// It uses exactly the shares given, so the caller is responsible for
+// supplying at least as many as the threshold Split was called with;
+// as documented above, supplying too few produces a wrong (but not
+// detectably wrong) secret rather than an error.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("sss: need at least one share")
+	}
 
+	secretLen := len(shares[0].Y)
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, sh := range shares {
+		if seen[sh.X] {
+			return nil, errors.New("sss: duplicate share x value")
+		}
+		seen[sh.X] = true
+		if len(sh.Y) != secretLen {
+			return nil, errors.New("sss: shares have different lengths")
+		}
+		xs[i] = sh.X
+	}
 
-      # If I have more shares than the threshold, the higher order coefficients
-      # (those greater than threshold) must be zero (by Lagrange)...
-      if resulting_poly[:self.threshold] + [0]*(len(shares)-self.threshold) != resulting_poly:
-        raise ValueError("Shares do not match.   Cannot decode")
-      
-      # track this byte...
-      mycoefficients.append(bytearray(resulting_poly))
-      
-      mysecretdata += chr(resulting_poly[0])
+	secret := make([]byte, secretLen)
+	for pos := 0; pos < secretLen; pos++ {
+		fxs := make([]byte, len(shares))
+		for i, sh := range shares {
+			fxs[i] = sh.Y[pos]
+		}
+		poly := full_lagrange(xs, fxs)
+		if len(poly) == 0 {
+			return nil, errors.New("sss: failed to recover secret")
+		}
+		secret[pos] = poly[0]
+	}
+	return secret, nil
+}
 
+// Marshal encodes a Share into a compact, self-checking binary format:
+// a 1-byte version, a 1-byte x, a 4-byte big-endian length followed by
+// that many y bytes, and a trailing 4-byte big-endian CRC32 (IEEE) of
+// everything before it.
+func (s Share) Marshal() ([]byte, error) {
+	buf := make([]byte, 1+1+4+len(s.Y)+4)
+	buf[0] = s.Version
+	buf[1] = s.X
+	binary.BigEndian.PutUint32(buf[2:6], uint32(len(s.Y)))
+	copy(buf[6:6+len(s.Y)], s.Y)
+
+	checksum := crc32.ChecksumIEEE(buf[:6+len(s.Y)])
+	binary.BigEndian.PutUint32(buf[6+len(s.Y):], checksum)
+	return buf, nil
+}
 
+// Unmarshal decodes a Share from the format written by Marshal, rejecting
+// it if the length framing doesn't fit or the trailing CRC32 doesn't
+// match.
+func (s *Share) Unmarshal(data []byte) error {
+	const headerLen = 1 + 1 + 4
+	if len(data) < headerLen+4 {
+		return errors.New("sss: share is too short")
+	}
 
-    # they check out!   Assign to the real ones!
-    self._coefficients = mycoefficients
+	version := data[0]
+	x := data[1]
+	yLen := binary.BigEndian.Uint32(data[2:6])
+	if uint64(headerLen)+uint64(yLen)+4 != uint64(len(data)) {
+		return errors.New("sss: share has incorrect length")
+	}
 
-    self.secretdata = mysecretdata
+	y := data[headerLen : headerLen+int(yLen)]
+	wantChecksum := binary.BigEndian.Uint32(data[headerLen+int(yLen):])
+	gotChecksum := crc32.ChecksumIEEE(data[:headerLen+int(yLen)])
+	if gotChecksum != wantChecksum {
+		return errors.New("sss: share failed CRC32 check")
+	}
 
-  
-*/
+	s.Version = version
+	s.X = x
+	s.Y = append([]byte{}, y...)
+	return nil
+}
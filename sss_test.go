@@ -1,53 +1,133 @@
 package sss
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
-/*
-Example:
-  import shamirsecret
-  # create a new object with some secret...
-  mysecret = shamirsecret.ShamirSecret(2, 'my shared secret')
-  # get shares out of it...
+func TestSplitCombineRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		n, t   int
+	}{
+		{"threshold of one", "my shared secret", 4, 1},
+		{"threshold equals n", "my shared secret", 4, 4},
+		{"typical threshold", "my shared secret", 5, 3},
+		{"empty secret", "", 3, 2},
+		{"single share", "x", 1, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shares, err := Split([]byte(tc.secret), tc.n, tc.t)
+			if err != nil {
+				t.Fatalf("Split failed: %v", err)
+			}
+			if len(shares) != tc.n {
+				t.Fatalf("got %d shares, want %d", len(shares), tc.n)
+			}
+
+			recovered, err := Combine(shares[:tc.t])
+			if err != nil {
+				t.Fatalf("Combine failed: %v", err)
+			}
+			if !bytes.Equal(recovered, []byte(tc.secret)) {
+				t.Fatalf("recovered %q, want %q", recovered, tc.secret)
+			}
+		})
+	}
+}
 
-  a = mysecret.compute_share(4)
-  b = mysecret.compute_share(6)
-  c = mysecret.compute_share(1)
-  d = mysecret.compute_share(2)
+func TestSplitRejectsBadParameters(t *testing.T) {
+	if _, err := Split([]byte("secret"), 5, 0); err == nil {
+		t.Error("expected error for threshold 0")
+	}
+	if _, err := Split([]byte("secret"), 5, 6); err == nil {
+		t.Error("expected error for threshold greater than n")
+	}
+	if _, err := Split([]byte("secret"), 0, 0); err == nil {
+		t.Error("expected error for n of 0")
+	}
+}
 
-  # Recover the secret value
-  newsecret = shamirsecret.ShamirSecret(2)
+func TestCombineRejectsDuplicateX(t *testing.T) {
+	shares, err := Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	bad := []Share{shares[0], shares[0], shares[1]}
+	if _, err := Combine(bad); err == nil {
+		t.Error("expected error for duplicate share x values")
+	}
+}
 
-  newsecret.recover_secretdata([a,b,c])  # note, two would do...
+func TestCombineRejectsMismatchedLengths(t *testing.T) {
+	shares, err := Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	bad := []Share{shares[0], shares[1], {Version: shareVersion, X: shares[2].X, Y: shares[2].Y[:len(shares[2].Y)-1]}}
+	if _, err := Combine(bad); err == nil {
+		t.Error("expected error for mismatched share lengths")
+	}
+}
 
-  # d should be okay...
-  assert(newsecret.is_valid_share(d))
+func TestShareMarshalUnmarshalRoundTrip(t *testing.T) {
+	shares, err := Split([]byte("my shared secret"), 5, 3)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
 
-  # change a byte
-  d[1][3] = d[1][3] - 1
+	for _, sh := range shares {
+		data, err := sh.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
 
-  # but not now...
-  assert(newsecret.is_valid_share(d) is False)
-*/
+		var got Share
+		if err := got.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if got.Version != sh.Version || got.X != sh.X || !bytes.Equal(got.Y, sh.Y) {
+			t.Fatalf("round-tripped share = %+v, want %+v", got, sh)
+		}
+	}
+}
 
-func TestSss(t *testing.T) {
-	mysecret := Shamir.Secret(2, "my shared secret")
+func TestShareUnmarshalRejectsCorruptedCRC(t *testing.T) {
+	shares, err := Split([]byte("my shared secret"), 5, 3)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	data, err := shares[0].Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
 
-	a := mysecret.Share(4)
-	b := mysecret.Share(6)
-	c := mysecret.Share(1)
-	d := mysecret.Share(2)
+	data[len(data)-1] ^= 0xFF
 
-	newsecret := Shamir.Secret(2)
-	newsecret.Recover(a, b, c)
-	if !newsecret.ValidShare(d) {
-		t.Logf("(%v) was an invalid share", d)
-		t.Fail()
+	var got Share
+	if err := got.Unmarshal(data); err == nil {
+		t.Error("expected error for corrupted CRC32")
 	}
+}
 
-	d[1][3] = d[1][3] - 1
+func TestShareUnmarshalRejectsWrongLength(t *testing.T) {
+	shares, err := Split([]byte("my shared secret"), 5, 3)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	data, err := shares[0].Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
 
-	if newsecret.ValidShare(d) {
-		t.Logf("(%v) was a valid share", d)
-		t.Fail()
+	var got Share
+	if err := got.Unmarshal(data[:len(data)-1]); err == nil {
+		t.Error("expected error for truncated share")
+	}
+	if err := got.Unmarshal(append(data, 0)); err == nil {
+		t.Error("expected error for share with trailing garbage")
 	}
 }
@@ -0,0 +1,253 @@
+// Streaming split/combine for secrets too large to hold in memory at once.
+//
+// Split/Combine (and the legacy incremental Shamir type in sss.go) keep the
+// whole secret's coefficients resident for the lifetime of the dealer,
+// which is fine for passwords and keys but impractical for disk images or
+// backup archives. SplitStream and CombineStream instead process the input
+// in fixed-size blocks, generating a fresh set of per-byte polynomials for
+// each block so memory use stays proportional to one block times the
+// number of shares, not to the size of the whole secret.
+
+package sss
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// streamMagic identifies a share stream produced by SplitStream.
+var streamMagic = [4]byte{'S', 'S', 'S', 's'}
+
+const streamVersion = 1
+
+// streamBlockSize is the default block size SplitStream processes the
+// input in: 64 KiB keeps per-block memory (one blockSize buffer per share)
+// modest while still amortizing the 4-byte length prefix on each block.
+const streamBlockSize = 64 * 1024
+
+// streamHeader is the fixed-size preamble written once at the start of
+// each share's output stream.
+type streamHeader struct {
+	Version   byte
+	Threshold byte
+	ShareID   byte
+	ChunkSize uint32
+}
+
+func (h streamHeader) write(w io.Writer) error {
+	buf := make([]byte, 4+1+1+1+4)
+	copy(buf[0:4], streamMagic[:])
+	buf[4] = h.Version
+	buf[5] = h.Threshold
+	buf[6] = h.ShareID
+	binary.BigEndian.PutUint32(buf[7:11], h.ChunkSize)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readStreamHeader(r io.Reader) (streamHeader, error) {
+	buf := make([]byte, 4+1+1+1+4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return streamHeader{}, err
+	}
+	var magic [4]byte
+	copy(magic[:], buf[0:4])
+	if magic != streamMagic {
+		return streamHeader{}, errors.New("sss: not an sss share stream")
+	}
+	h := streamHeader{
+		Version:   buf[4],
+		Threshold: buf[5],
+		ShareID:   buf[6],
+		ChunkSize: binary.BigEndian.Uint32(buf[7:11]),
+	}
+	if h.Version != streamVersion {
+		return streamHeader{}, errors.New("sss: unsupported share stream version")
+	}
+	return h, nil
+}
+
+// SplitStream reads in to completion, splitting it into numShares GF(256)
+// Shamir shares that can be recombined by any threshold of them, and
+// writes one share to each of outs. Each block of up to 64 KiB gets its
+// own fresh random coefficients, so memory use is independent of the size
+// of in.
+func SplitStream(threshold, numShares int, in io.Reader, outs []io.Writer) error {
+	if threshold < 1 || threshold > 255 {
+		return errors.New("sss: threshold must be between 1 and 255")
+	}
+	if numShares < threshold || numShares > 255 {
+		return errors.New("sss: numShares must be between threshold and 255")
+	}
+	if len(outs) != numShares {
+		return errors.New("sss: outs must have numShares writers")
+	}
+
+	for id, out := range outs {
+		header := streamHeader{
+			Version:   streamVersion,
+			Threshold: byte(threshold),
+			ShareID:   byte(id + 1),
+			ChunkSize: streamBlockSize,
+		}
+		if err := header.write(out); err != nil {
+			return err
+		}
+	}
+
+	block := make([]byte, streamBlockSize)
+	for {
+		n, readErr := io.ReadFull(in, block)
+		if n > 0 {
+			if err := splitBlock(threshold, numShares, block[:n], outs); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// splitBlock shares a single block across outs, using fresh coefficients
+// for every byte of the block.
+func splitBlock(threshold, numShares int, block []byte, outs []io.Writer) error {
+	shareBytes := make([][]byte, numShares)
+	for i := range shareBytes {
+		shareBytes[i] = make([]byte, len(block))
+	}
+
+	coefs := make([]byte, threshold)
+	for pos, secretByte := range block {
+		coefs[0] = secretByte
+		if _, err := io.ReadFull(rand.Reader, coefs[1:]); err != nil {
+			return err
+		}
+		for id := 1; id <= numShares; id++ {
+			shareBytes[id-1][pos] = f(byte(id), coefs)
+		}
+	}
+
+	lenBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lenBuf, uint32(len(block)))
+	for i, out := range outs {
+		if _, err := out.Write(lenBuf); err != nil {
+			return err
+		}
+		if _, err := out.Write(shareBytes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CombineStream reads a share stream from each of ins (as written by
+// SplitStream), recombines them block by block, and writes the recovered
+// secret to out. At least threshold of the ins must come from the same
+// SplitStream call; ins may be given in any order and extras beyond
+// threshold are tolerated as long as they all agree.
+func CombineStream(threshold int, ins []io.Reader, out io.Writer) error {
+	if len(ins) < threshold {
+		return errors.New("sss: not enough input streams to meet threshold")
+	}
+
+	headers := make([]streamHeader, len(ins))
+	ids := make([]byte, len(ins))
+	seen := make(map[byte]bool, len(ins))
+	for i, in := range ins {
+		h, err := readStreamHeader(in)
+		if err != nil {
+			return err
+		}
+		if int(h.Threshold) != threshold {
+			return errors.New("sss: share stream threshold does not match")
+		}
+		if seen[h.ShareID] {
+			return errors.New("sss: duplicate share id in input streams")
+		}
+		seen[h.ShareID] = true
+		headers[i] = h
+		ids[i] = h.ShareID
+	}
+	chunkSize := headers[0].ChunkSize
+	for _, h := range headers[1:] {
+		if h.ChunkSize != chunkSize {
+			return errors.New("sss: share streams have mismatched chunk sizes")
+		}
+	}
+
+	lenBuf := make([]byte, 4)
+	blocks := make([][]byte, len(ins))
+	for {
+		blockLen := uint32(0)
+		for i, in := range ins {
+			_, err := io.ReadFull(in, lenBuf)
+			if err == io.EOF {
+				if i == 0 {
+					return nil
+				}
+				return errors.New("sss: share streams ended at different lengths")
+			}
+			if err != nil {
+				return err
+			}
+			l := binary.BigEndian.Uint32(lenBuf)
+			if i == 0 {
+				blockLen = l
+			} else if l != blockLen {
+				return errors.New("sss: share streams have mismatched block lengths")
+			}
+			blocks[i] = make([]byte, l)
+			if _, err := io.ReadFull(in, blocks[i]); err != nil {
+				return err
+			}
+		}
+
+		recovered, err := combineBlock(threshold, ids, blocks)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(recovered); err != nil {
+			return err
+		}
+	}
+}
+
+// combineBlock recovers one block's worth of plaintext from byte-wise
+// shares, interpolating each position independently from the first
+// threshold streams, then checking that any streams beyond threshold agree
+// with the interpolated polynomial, as CombineStream documents.
+func combineBlock(threshold int, ids []byte, blocks [][]byte) ([]byte, error) {
+	blockLen := len(blocks[0])
+	recovered := make([]byte, blockLen)
+
+	xs := ids[:threshold]
+	polys := make([][]byte, blockLen)
+	for pos := 0; pos < blockLen; pos++ {
+		fxs := make([]byte, threshold)
+		for i := 0; i < threshold; i++ {
+			fxs[i] = blocks[i][pos]
+		}
+		poly := full_lagrange(xs, fxs)
+		if len(poly) == 0 {
+			return nil, errors.New("sss: failed to recover block")
+		}
+		recovered[pos] = poly[0]
+		polys[pos] = poly
+	}
+
+	for i := threshold; i < len(ids); i++ {
+		for pos := 0; pos < blockLen; pos++ {
+			if f(ids[i], polys[pos]) != blocks[i][pos] {
+				return nil, errors.New("sss: extra share streams disagree with the recovered secret")
+			}
+		}
+	}
+
+	return recovered, nil
+}
@@ -0,0 +1,128 @@
+package sss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	const threshold = 3
+	const numShares = 5
+	// A few megabytes, and not an exact multiple of the block size, so we
+	// exercise the final short block too.
+	secret := make([]byte, 3*1024*1024+17)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		t.Fatalf("failed to generate random secret: %v", err)
+	}
+
+	shareBufs := make([]*bytes.Buffer, numShares)
+	outs := make([]io.Writer, numShares)
+	for i := range shareBufs {
+		shareBufs[i] = &bytes.Buffer{}
+		outs[i] = shareBufs[i]
+	}
+
+	if err := SplitStream(threshold, numShares, bytes.NewReader(secret), outs); err != nil {
+		t.Fatalf("SplitStream failed: %v", err)
+	}
+
+	// Use an arbitrary subset of size threshold to make sure CombineStream
+	// doesn't assume shares are given in share-id order.
+	ins := []io.Reader{
+		bytes.NewReader(shareBufs[4].Bytes()),
+		bytes.NewReader(shareBufs[0].Bytes()),
+		bytes.NewReader(shareBufs[2].Bytes()),
+	}
+
+	var recovered bytes.Buffer
+	if err := CombineStream(threshold, ins, &recovered); err != nil {
+		t.Fatalf("CombineStream failed: %v", err)
+	}
+
+	if !bytes.Equal(recovered.Bytes(), secret) {
+		t.Fatalf("recovered secret does not match original: got %d bytes, want %d bytes", recovered.Len(), len(secret))
+	}
+}
+
+func TestCombineStreamToleratesAgreeingExtras(t *testing.T) {
+	const threshold = 3
+	const numShares = 5
+	secret := make([]byte, 1024+5)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		t.Fatalf("failed to generate random secret: %v", err)
+	}
+
+	shareBufs := make([]*bytes.Buffer, numShares)
+	outs := make([]io.Writer, numShares)
+	for i := range shareBufs {
+		shareBufs[i] = &bytes.Buffer{}
+		outs[i] = shareBufs[i]
+	}
+
+	if err := SplitStream(threshold, numShares, bytes.NewReader(secret), outs); err != nil {
+		t.Fatalf("SplitStream failed: %v", err)
+	}
+
+	// One more stream than threshold, all from the same SplitStream call,
+	// so they must agree.
+	ins := []io.Reader{
+		bytes.NewReader(shareBufs[0].Bytes()),
+		bytes.NewReader(shareBufs[1].Bytes()),
+		bytes.NewReader(shareBufs[2].Bytes()),
+		bytes.NewReader(shareBufs[3].Bytes()),
+	}
+
+	var recovered bytes.Buffer
+	if err := CombineStream(threshold, ins, &recovered); err != nil {
+		t.Fatalf("CombineStream failed: %v", err)
+	}
+	if !bytes.Equal(recovered.Bytes(), secret) {
+		t.Fatalf("recovered secret does not match original: got %d bytes, want %d bytes", recovered.Len(), len(secret))
+	}
+}
+
+func TestCombineStreamRejectsDisagreeingExtras(t *testing.T) {
+	const threshold = 3
+	const numShares = 4
+	secretA := make([]byte, 64)
+	if _, err := io.ReadFull(rand.Reader, secretA); err != nil {
+		t.Fatalf("failed to generate random secret: %v", err)
+	}
+	secretB := make([]byte, 64)
+	if _, err := io.ReadFull(rand.Reader, secretB); err != nil {
+		t.Fatalf("failed to generate random secret: %v", err)
+	}
+
+	splitInto := func(secret []byte) []*bytes.Buffer {
+		bufs := make([]*bytes.Buffer, numShares)
+		outs := make([]io.Writer, numShares)
+		for i := range bufs {
+			bufs[i] = &bytes.Buffer{}
+			outs[i] = bufs[i]
+		}
+		if err := SplitStream(threshold, numShares, bytes.NewReader(secret), outs); err != nil {
+			t.Fatalf("SplitStream failed: %v", err)
+		}
+		return bufs
+	}
+
+	sharesA := splitInto(secretA)
+	sharesB := splitInto(secretB)
+
+	// Three honest shares of secretA plus one share from an unrelated
+	// SplitStream call of secretB: the extra does not agree and must be
+	// rejected, not silently ignored.
+	ins := []io.Reader{
+		bytes.NewReader(sharesA[0].Bytes()),
+		bytes.NewReader(sharesA[1].Bytes()),
+		bytes.NewReader(sharesA[2].Bytes()),
+		bytes.NewReader(sharesB[3].Bytes()),
+	}
+
+	var recovered bytes.Buffer
+	if err := CombineStream(threshold, ins, &recovered); err == nil {
+		t.Error("expected error for a disagreeing extra share stream")
+	}
+}